@@ -0,0 +1,70 @@
+package poly
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ClientIP returns the IP address of the client that made req.
+//
+// When the peer in req.RemoteAddr is in trustedProxies the X-Forwarded-For header is walked from
+// right to left and the first address not in trustedProxies is returned; if X-Forwarded-For is
+// absent then X-Real-IP is used.  Headers from untrusted peers are ignored so clients cannot spoof
+// their address.  If no forwarded address is available the peer address is returned.
+func ClientIP(req *http.Request, trustedProxies []net.IPNet) string {
+	peer := remoteIP(req)
+	if !ipTrusted(peer, trustedProxies) {
+		return peer
+	}
+	if values := req.Header.Values("X-Forwarded-For"); len(values) > 0 {
+		hops := strings.Split(strings.Join(values, ","), ",")
+		client := peer
+		for k := len(hops) - 1; k >= 0; k-- {
+			hop := strings.TrimSpace(hops[k])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			client = hop
+			if !ipTrusted(hop, trustedProxies) {
+				break
+			}
+		}
+		return client
+	}
+	if realIP := strings.TrimSpace(req.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+	return peer
+}
+
+// ClientIPKey returns a KeyFunc that identifies requests by ClientIP with the given trusted proxies.
+// Use it with RateLimitBy when the server is behind a proxy.
+func ClientIPKey(trustedProxies []net.IPNet) KeyFunc {
+	return func(req *http.Request) string {
+		return ClientIP(req, trustedProxies)
+	}
+}
+
+// ipTrusted returns true if addr is an IP address contained in one of nets.
+func ipTrusted(addr string, nets []net.IPNet) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// remoteIP returns the host portion of req.RemoteAddr.
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,60 @@
+package poly_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nofeaturesonlybugs/poly"
+)
+
+func TestClientIP(t *testing.T) {
+	var trusted []net.IPNet
+	for _, cidr := range []string{"10.0.0.0/8", "fd00::/8"} {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trusted = append(trusted, *n)
+	}
+	type Test struct {
+		Name   string
+		Remote string
+		XFF    string
+		RealIP string
+		Expect string
+	}
+	tests := []Test{
+		{Name: "direct", Remote: "203.0.113.7:51000", Expect: "203.0.113.7"},
+		{Name: "direct ipv6", Remote: "[2001:db8::1]:51000", Expect: "2001:db8::1"},
+		{Name: "untrusted spoof forwarded", Remote: "203.0.113.7:51000", XFF: "198.51.100.1", Expect: "203.0.113.7"},
+		{Name: "untrusted spoof real ip", Remote: "203.0.113.7:51000", RealIP: "198.51.100.1", Expect: "203.0.113.7"},
+		{Name: "trusted proxy", Remote: "10.0.0.1:443", XFF: "198.51.100.1", Expect: "198.51.100.1"},
+		{Name: "trusted proxy chain", Remote: "10.0.0.1:443", XFF: "198.51.100.1, 10.0.0.2, 10.0.0.3", Expect: "198.51.100.1"},
+		{Name: "trusted proxy client spoof", Remote: "10.0.0.1:443", XFF: "192.0.2.66, 198.51.100.1, 10.0.0.2", Expect: "198.51.100.1"},
+		{Name: "trusted proxy invalid hop", Remote: "10.0.0.1:443", XFF: "garbage, 10.0.0.2", Expect: "10.0.0.2"},
+		{Name: "trusted proxy real ip", Remote: "10.0.0.1:443", RealIP: "198.51.100.1", Expect: "198.51.100.1"},
+		{Name: "trusted proxy forwarded wins", Remote: "10.0.0.1:443", XFF: "198.51.100.1", RealIP: "192.0.2.66", Expect: "198.51.100.1"},
+		{Name: "trusted proxy no headers", Remote: "10.0.0.1:443", Expect: "10.0.0.1"},
+		{Name: "trusted proxy ipv6", Remote: "[fd00::1]:443", XFF: "2001:db8::2", Expect: "2001:db8::2"},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = test.Remote
+			if test.XFF != "" {
+				req.Header.Set("X-Forwarded-For", test.XFF)
+			}
+			if test.RealIP != "" {
+				req.Header.Set("X-Real-IP", test.RealIP)
+			}
+			if got := poly.ClientIP(req, trusted); got != test.Expect {
+				t.Fatalf("expected %v; got %v", test.Expect, got)
+			}
+			if got := poly.ClientIPKey(trusted)(req); got != test.Expect {
+				t.Fatalf("expected key %v; got %v", test.Expect, got)
+			}
+		})
+	}
+}
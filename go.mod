@@ -0,0 +1,3 @@
+module github.com/nofeaturesonlybugs/poly
+
+go 1.23
@@ -0,0 +1,45 @@
+package poly
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// HealthCheck is a named check run by HealthHandler.
+type HealthCheck struct {
+	// Name identifies the check in the response body when it fails.
+	Name string
+	// Check returns a non-nil error when the checked dependency is unhealthy.
+	Check func() error
+}
+
+// healthStatus is the JSON body written by HealthHandler.
+type healthStatus struct {
+	Status string   `json:"status"`
+	Failed []string `json:"failed,omitempty"`
+}
+
+// HealthHandler returns an http.Handler that runs checks in order on every request.
+//
+// When all checks return nil the response is 200 with body {"status":"ok"}.  Otherwise the
+// response is 503 and the body lists the names of the failing checks:
+//
+//	{"status":"unavailable","failed":["database"]}
+//
+// Check errors are not included in the response body.
+func HealthHandler(checks ...HealthCheck) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, status := healthStatus{Status: "ok"}, http.StatusOK
+		for _, check := range checks {
+			if err := check.Check(); err != nil {
+				body.Failed = append(body.Failed, check.Name)
+			}
+		}
+		if len(body.Failed) > 0 {
+			body.Status, status = "unavailable", http.StatusServiceUnavailable
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	})
+}
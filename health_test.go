@@ -0,0 +1,62 @@
+package poly_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nofeaturesonlybugs/poly"
+)
+
+func TestHealthHandler(t *testing.T) {
+	pass := func() error { return nil }
+	fail := func() error { return errors.New("connection refused") }
+	type Test struct {
+		Name   string
+		Checks []poly.HealthCheck
+		Status int
+		Body   string
+	}
+	tests := []Test{
+		{
+			Name:   "no checks",
+			Status: http.StatusOK,
+			Body:   `{"status":"ok"}`,
+		},
+		{
+			Name:   "all pass",
+			Checks: []poly.HealthCheck{{Name: "database", Check: pass}, {Name: "cache", Check: pass}},
+			Status: http.StatusOK,
+			Body:   `{"status":"ok"}`,
+		},
+		{
+			Name:   "one failing",
+			Checks: []poly.HealthCheck{{Name: "database", Check: pass}, {Name: "cache", Check: fail}},
+			Status: http.StatusServiceUnavailable,
+			Body:   `{"status":"unavailable","failed":["cache"]}`,
+		},
+		{
+			Name:   "all failing",
+			Checks: []poly.HealthCheck{{Name: "primary", Check: fail}, {Name: "replica", Check: fail}},
+			Status: http.StatusServiceUnavailable,
+			Body:   `{"status":"unavailable","failed":["primary","replica"]}`,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			poly.HealthHandler(test.Checks...).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+			if w.Code != test.Status {
+				t.Fatalf("expected status %v; got %v", test.Status, w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != "application/json" {
+				t.Fatalf("expected application/json; got %q", got)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != test.Body {
+				t.Fatalf("expected body %v; got %v", test.Body, got)
+			}
+		})
+	}
+}
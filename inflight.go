@@ -0,0 +1,49 @@
+package poly
+
+import (
+	"net/http"
+)
+
+// MaxInFlight returns middleware that allows at most n requests to run at once.  Requests arriving
+// while n are in flight receive 503 Service Unavailable.  If n is not positive then requests are
+// not limited.
+//
+// A request's slot is released when the next handler returns, including when it panics.
+func MaxInFlight(n int) func(http.Handler) http.Handler {
+	return maxInFlight(n, false)
+}
+
+// MaxInFlightWait is MaxInFlight except requests wait for a free slot instead of being rejected.
+// A request whose context is done while waiting receives 503 Service Unavailable.
+func MaxInFlightWait(n int) func(http.Handler) http.Handler {
+	return maxInFlight(n, true)
+}
+
+// maxInFlight implements MaxInFlight and MaxInFlightWait.
+func maxInFlight(n int, wait bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if n <= 0 {
+			return next
+		}
+		sem := make(chan struct{}, n)
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if wait {
+				select {
+				case sem <- struct{}{}:
+				case <-req.Context().Done():
+					http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+					return
+				}
+			} else {
+				select {
+				case sem <- struct{}{}:
+				default:
+					http.Error(w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+					return
+				}
+			}
+			defer func() { <-sem }()
+			next.ServeHTTP(w, req)
+		})
+	}
+}
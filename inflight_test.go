@@ -0,0 +1,120 @@
+package poly_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nofeaturesonlybugs/poly"
+)
+
+// blockingHandler blocks requests for /block until release is closed and panics for /panic.
+type blockingHandler struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (h *blockingHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.URL.Path {
+	case "/block":
+		h.entered <- struct{}{}
+		<-h.release
+	case "/panic":
+		panic("handler panic")
+	}
+}
+
+func serveStatus(handler http.Handler, req *http.Request) int {
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestMaxInFlight(t *testing.T) {
+	const n = 2
+	next := newBlockingHandler()
+	handler := poly.MaxInFlight(n)(next)
+	var wg sync.WaitGroup
+	for k := 0; k < n; k++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			serveStatus(handler, httptest.NewRequest(http.MethodGet, "/block", nil))
+		}()
+		<-next.entered
+	}
+	if got := serveStatus(handler, httptest.NewRequest(http.MethodGet, "/", nil)); got != http.StatusServiceUnavailable {
+		t.Fatalf("expected request %v to be shed with %v; got %v", n+1, http.StatusServiceUnavailable, got)
+	}
+	close(next.release)
+	wg.Wait()
+	for k := 0; k < n+1; k++ {
+		if got := serveStatus(handler, httptest.NewRequest(http.MethodGet, "/", nil)); got != http.StatusOK {
+			t.Fatalf("expected capacity to recover; got %v", got)
+		}
+	}
+}
+
+func TestMaxInFlightPanic(t *testing.T) {
+	handler := poly.MaxInFlight(1)(newBlockingHandler())
+	for k := 0; k < 3; k++ {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Fatal("expected panic")
+				}
+			}()
+			serveStatus(handler, httptest.NewRequest(http.MethodGet, "/panic", nil))
+		}()
+	}
+	if got := serveStatus(handler, httptest.NewRequest(http.MethodGet, "/", nil)); got != http.StatusOK {
+		t.Fatalf("expected slot released after panic; got %v", got)
+	}
+}
+
+func TestMaxInFlightWait(t *testing.T) {
+	next := newBlockingHandler()
+	handler := poly.MaxInFlightWait(1)(next)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		serveStatus(handler, httptest.NewRequest(http.MethodGet, "/block", nil))
+	}()
+	<-next.entered
+	//
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+	if got := serveStatus(handler, req); got != http.StatusServiceUnavailable {
+		t.Fatalf("expected %v when context ends while waiting; got %v", http.StatusServiceUnavailable, got)
+	}
+	//
+	waited := make(chan int)
+	go func() {
+		waited <- serveStatus(handler, httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+	select {
+	case got := <-waited:
+		t.Fatalf("expected request to wait for a slot; got %v", got)
+	case <-time.After(10 * time.Millisecond):
+	}
+	close(next.release)
+	<-done
+	if got := <-waited; got != http.StatusOK {
+		t.Fatalf("expected waiting request to proceed; got %v", got)
+	}
+}
+
+func TestMaxInFlightDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+	if got := serveStatus(poly.MaxInFlight(0)(next), httptest.NewRequest(http.MethodGet, "/", nil)); got != http.StatusOK {
+		t.Fatalf("expected unlimited requests; got %v", got)
+	}
+}
@@ -0,0 +1,125 @@
+package poly
+
+import (
+	"container/list"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// rateLimitMaxKeys bounds the number of clients tracked by a single RateLimit middleware.
+const rateLimitMaxKeys = 10000
+
+// KeyFunc returns the key used to group a request with others, such as a client IP address, an API
+// token, or a user ID.
+type KeyFunc func(*http.Request) string
+
+// RateLimit returns middleware that limits each client to rps requests per second with bursts of
+// up to burst requests.  Clients are identified by the IP address in req.RemoteAddr.
+//
+// Requests exceeding the limit receive 429 Too Many Requests with a Retry-After header.  A burst
+// less than 1 is treated as 1; if rps is not positive then requests are not limited.
+func RateLimit(rps int, burst int) func(http.Handler) http.Handler {
+	return RateLimitBy(rps, burst, nil)
+}
+
+// RateLimitBy is RateLimit with clients identified by key; requests with the same key share a
+// token bucket.  A nil key identifies clients by the IP address in req.RemoteAddr.
+func RateLimitBy(rps int, burst int, key KeyFunc) func(http.Handler) http.Handler {
+	if key == nil {
+		key = remoteIP
+	}
+	return func(next http.Handler) http.Handler {
+		if rps <= 0 {
+			return next
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		limiter := &rateLimiter{
+			rate:    float64(rps),
+			burst:   float64(burst),
+			maxKeys: rateLimitMaxKeys,
+			buckets: map[string]*list.Element{},
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if wait, ok := limiter.allow(key(req), time.Now()); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+				return
+			}
+			next.ServeHTTP(w, req)
+		})
+	}
+}
+
+// tokenBucket is the state for a single client of a rateLimiter.
+type tokenBucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a set of token buckets keyed by client.
+type rateLimiter struct {
+	rate    float64
+	burst   float64
+	maxKeys int
+	//
+	mu      sync.Mutex
+	buckets map[string]*list.Element
+	// lru orders buckets by last use with the most recent at the front.
+	lru list.List
+}
+
+// allow takes a token from the bucket for key.  When no token is available it returns false and
+// the duration until the next token is available.
+func (l *rateLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var b *tokenBucket
+	if elem, ok := l.buckets[key]; ok {
+		b = elem.Value.(*tokenBucket)
+		l.lru.MoveToFront(elem)
+	} else {
+		l.evict(now)
+		b = &tokenBucket{key: key, tokens: l.burst, last: now}
+		l.buckets[key] = l.lru.PushFront(b)
+	}
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
+
+// evict makes room for a new bucket.  Least recently used buckets that have refilled are removed
+// since they are indistinguishable from new ones; if the limiter is still full then the least
+// recently used bucket is removed.  Clients that keep making requests stay recently used so they
+// are evicted last.
+//
+// evict must be called with l.mu held.
+func (l *rateLimiter) evict(now time.Time) {
+	for elem := l.lru.Back(); elem != nil; elem = l.lru.Back() {
+		b := elem.Value.(*tokenBucket)
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate < l.burst {
+			break
+		}
+		l.remove(elem)
+	}
+	if l.maxKeys > 0 && len(l.buckets) >= l.maxKeys {
+		l.remove(l.lru.Back())
+	}
+}
+
+// remove deletes the bucket held by elem.
+//
+// remove must be called with l.mu held.
+func (l *rateLimiter) remove(elem *list.Element) {
+	delete(l.buckets, elem.Value.(*tokenBucket).key)
+	l.lru.Remove(elem)
+}
@@ -0,0 +1,139 @@
+package poly
+
+import (
+	"container/list"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func newTestLimiter(rps, burst, maxKeys int) *rateLimiter {
+	return &rateLimiter{
+		rate:    float64(rps),
+		burst:   float64(burst),
+		maxKeys: maxKeys,
+		buckets: map[string]*list.Element{},
+	}
+}
+
+func TestRateLimiterAllow(t *testing.T) {
+	limiter := newTestLimiter(2, 3, rateLimitMaxKeys)
+	now := time.Unix(1000, 0)
+	for k := 0; k < 3; k++ {
+		if _, ok := limiter.allow("client", now); !ok {
+			t.Fatalf("expected request %v within burst to pass", k)
+		}
+	}
+	wait, ok := limiter.allow("client", now)
+	if ok {
+		t.Fatal("expected request beyond burst to be limited")
+	}
+	if wait != 500*time.Millisecond {
+		t.Fatalf("expected wait of 500ms; got %v", wait)
+	}
+	if _, ok = limiter.allow("client", now.Add(wait/2)); ok {
+		t.Fatal("expected request before refill to be limited")
+	}
+	if _, ok = limiter.allow("client", now.Add(wait)); !ok {
+		t.Fatal("expected request after refill to pass")
+	}
+	if _, ok = limiter.allow("client", now.Add(wait)); ok {
+		t.Fatal("expected refill to add a single token")
+	}
+	if _, ok = limiter.allow("client", now.Add(time.Hour)); !ok {
+		t.Fatal("expected request after full refill to pass")
+	}
+}
+
+func TestRateLimiterEvict(t *testing.T) {
+	limiter := newTestLimiter(1, 1, 3)
+	now := time.Unix(1000, 0)
+	limiter.allow("throttled", now)
+	for k := 0; k < 10; k++ {
+		// The throttled client keeps retrying while other clients churn through fresh keys.
+		if _, ok := limiter.allow("throttled", now); ok {
+			t.Fatalf("iteration %v: expected throttled client to stay limited", k)
+		}
+		limiter.allow("churn-"+strconv.Itoa(k), now)
+		if len(limiter.buckets) > 3 || limiter.lru.Len() != len(limiter.buckets) {
+			t.Fatalf("iteration %v: expected at most 3 buckets; got %v map and %v list", k, len(limiter.buckets), limiter.lru.Len())
+		}
+	}
+	if _, ok := limiter.buckets["churn-0"]; ok {
+		t.Fatal("expected least recently used bucket to be evicted")
+	}
+	// Refilled buckets are evicted before active ones.
+	later := now.Add(time.Hour)
+	limiter.allow("throttled", later)
+	limiter.allow("fresh", later)
+	if _, ok := limiter.buckets["throttled"]; !ok {
+		t.Fatal("expected recently used bucket to be kept")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	handler := RateLimit(1, 2)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	serve := func(remote string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = remote
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+	for k := 0; k < 2; k++ {
+		if w := serve("192.0.2.1:1234"); w.Code != http.StatusOK {
+			t.Fatalf("expected request %v within burst to pass; got %v", k, w.Code)
+		}
+	}
+	w := serve("192.0.2.1:5678")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected %v; got %v", http.StatusTooManyRequests, w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Fatalf("expected Retry-After 1; got %q", got)
+	}
+	if w = serve("192.0.2.2:1234"); w.Code != http.StatusOK {
+		t.Fatalf("expected other client to pass; got %v", w.Code)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+	handler := RateLimit(0, 1)(next)
+	for k := 0; k < 10; k++ {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected unlimited requests; got %v", w.Code)
+		}
+	}
+}
+
+func TestRateLimitBy(t *testing.T) {
+	key := func(req *http.Request) string {
+		return req.Header.Get("X-Api-Key")
+	}
+	handler := RateLimitBy(1, 1, key)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	tests := []struct {
+		Key    string
+		Status int
+	}{
+		{"a", http.StatusOK},
+		{"a", http.StatusTooManyRequests},
+		{"b", http.StatusOK},
+		{"b", http.StatusTooManyRequests},
+		{"a", http.StatusTooManyRequests},
+	}
+	for k, test := range tests {
+		// Every request comes from the same address so only the key separates the buckets.
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Api-Key", test.Key)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != test.Status {
+			t.Fatalf("request %v with key %q: expected %v; got %v", k, test.Key, test.Status, w.Code)
+		}
+	}
+}
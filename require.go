@@ -0,0 +1,70 @@
+package poly
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// missingHeaders is the JSON body written by RequireHeaders.
+type missingHeaders struct {
+	Error   string   `json:"error"`
+	Missing []string `json:"missing"`
+}
+
+// RequireHeaders returns middleware that rejects requests missing any of the named headers.
+//
+// Rejected requests receive 400 Bad Request naming every missing header.  The body is JSON only
+// when the request's Accept headers explicitly list application/json with a non-zero quality;
+// wildcards such as */* and application/* receive plain text.
+func RequireHeaders(names ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var missing []string
+			for _, name := range names {
+				if req.Header.Get(name) == "" {
+					missing = append(missing, name)
+				}
+			}
+			if len(missing) == 0 {
+				next.ServeHTTP(w, req)
+				return
+			}
+			if acceptsJSON(req) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Header().Set("X-Content-Type-Options", "nosniff")
+				w.WriteHeader(http.StatusBadRequest)
+				_ = json.NewEncoder(w).Encode(missingHeaders{Error: "missing required headers", Missing: missing})
+				return
+			}
+			http.Error(w, "missing required headers: "+strings.Join(missing, ", "), http.StatusBadRequest)
+		})
+	}
+}
+
+// acceptsJSON returns true if an Accept header of req lists application/json with a non-zero
+// quality.
+func acceptsJSON(req *http.Request) bool {
+	for _, value := range req.Header.Values("Accept") {
+		for _, mediaRange := range strings.Split(value, ",") {
+			params := strings.Split(mediaRange, ";")
+			if !strings.EqualFold(strings.TrimSpace(params[0]), "application/json") {
+				continue
+			}
+			q := 1.0
+			for _, param := range params[1:] {
+				if k, v, ok := strings.Cut(param, "="); ok && strings.EqualFold(strings.TrimSpace(k), "q") {
+					var err error
+					if q, err = strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+						q = 0
+					}
+				}
+			}
+			if q > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
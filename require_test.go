@@ -0,0 +1,106 @@
+package poly_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nofeaturesonlybugs/poly"
+)
+
+func TestRequireHeaders(t *testing.T) {
+	type Test struct {
+		Name        string
+		Headers     map[string]string
+		Accept      []string
+		Status      int
+		ContentType string
+		Body        string
+	}
+	tests := []Test{
+		{
+			Name:    "all present",
+			Headers: map[string]string{"X-Api-Version": "2", "X-Tenant-Id": "acme"},
+			Status:  http.StatusOK,
+			Body:    "ok",
+		},
+		{
+			Name:        "one missing",
+			Headers:     map[string]string{"X-Api-Version": "2"},
+			Status:      http.StatusBadRequest,
+			ContentType: "text/plain; charset=utf-8",
+			Body:        "missing required headers: X-Tenant-Id",
+		},
+		{
+			Name:        "all missing",
+			Status:      http.StatusBadRequest,
+			ContentType: "text/plain; charset=utf-8",
+			Body:        "missing required headers: X-Api-Version, X-Tenant-Id",
+		},
+		{
+			Name:        "one missing json",
+			Headers:     map[string]string{"X-Tenant-Id": "acme", "Accept": "application/json"},
+			Status:      http.StatusBadRequest,
+			ContentType: "application/json",
+			Body:        `{"error":"missing required headers","missing":["X-Api-Version"]}`,
+		},
+		{
+			Name:        "one missing json with parameters",
+			Headers:     map[string]string{"X-Tenant-Id": "acme", "Accept": "text/html;q=0.9, Application/JSON; charset=utf-8; q=0.5"},
+			Status:      http.StatusBadRequest,
+			ContentType: "application/json",
+			Body:        `{"error":"missing required headers","missing":["X-Api-Version"]}`,
+		},
+		{
+			Name:        "one missing json refused",
+			Headers:     map[string]string{"X-Tenant-Id": "acme", "Accept": "application/json;q=0, text/plain"},
+			Status:      http.StatusBadRequest,
+			ContentType: "text/plain; charset=utf-8",
+			Body:        "missing required headers: X-Api-Version",
+		},
+		{
+			Name:        "one missing json second accept line",
+			Headers:     map[string]string{"X-Tenant-Id": "acme", "Accept": "text/plain;q=0.1"},
+			Accept:      []string{"application/json"},
+			Status:      http.StatusBadRequest,
+			ContentType: "application/json",
+			Body:        `{"error":"missing required headers","missing":["X-Api-Version"]}`,
+		},
+		{
+			Name:        "one missing wildcard",
+			Headers:     map[string]string{"X-Tenant-Id": "acme", "Accept": "*/*"},
+			Status:      http.StatusBadRequest,
+			ContentType: "text/plain; charset=utf-8",
+			Body:        "missing required headers: X-Api-Version",
+		},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	handler := poly.RequireHeaders("X-Api-Version", "X-Tenant-Id")(next)
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for name, value := range test.Headers {
+				req.Header.Set(name, value)
+			}
+			for _, value := range test.Accept {
+				req.Header.Add("Accept", value)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if w.Code != test.Status {
+				t.Fatalf("expected status %v; got %v", test.Status, w.Code)
+			}
+			if test.ContentType != "" {
+				if got := w.Header().Get("Content-Type"); got != test.ContentType {
+					t.Fatalf("expected Content-Type %q; got %q", test.ContentType, got)
+				}
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != test.Body {
+				t.Fatalf("expected body %v; got %v", test.Body, got)
+			}
+		})
+	}
+}
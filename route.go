@@ -0,0 +1,37 @@
+package poly
+
+import (
+	"context"
+	"net/http"
+)
+
+// routeKey is the context key for the route pattern stored by RecordRoute and WithRoute.
+type routeKey struct{}
+
+// RouteFromContext returns the route pattern stored in ctx by RecordRoute or WithRoute, such as
+// "GET /users/{id}", or the empty string if there is none.
+//
+// The pattern is suitable as a low cardinality label for logging and metrics.
+func RouteFromContext(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey{}).(string)
+	return route
+}
+
+// RecordRoute returns a handler that stores req.Pattern in the request context before calling next.
+//
+// req.Pattern is set by http.ServeMux (Go 1.23 and later) when it routes a request so RecordRoute
+// must wrap the handler registered with the mux rather than the mux itself:
+//
+//	mux.Handle("GET /users/{id}", poly.RecordRoute(handler))
+func RecordRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), routeKey{}, req.Pattern)))
+	})
+}
+
+// WithRoute is RecordRoute with an explicit pattern for routers that do not set req.Pattern.
+func WithRoute(pattern string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		next.ServeHTTP(w, req.WithContext(context.WithValue(req.Context(), routeKey{}, pattern)))
+	})
+}
@@ -0,0 +1,40 @@
+package poly_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nofeaturesonlybugs/poly"
+)
+
+func TestRouteFromContext(t *testing.T) {
+	var route string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		route = poly.RouteFromContext(req.Context())
+	})
+	mux := http.NewServeMux()
+	mux.Handle("GET /users/{id}", poly.RecordRoute(handler))
+	mux.Handle("/legacy/", poly.WithRoute("/legacy/:id", handler))
+	//
+	tests := []struct {
+		Path   string
+		Expect string
+	}{
+		{"/users/42", "GET /users/{id}"},
+		{"/legacy/42", "/legacy/:id"},
+	}
+	for _, test := range tests {
+		t.Run(test.Path, func(t *testing.T) {
+			route = ""
+			mux.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, test.Path, nil))
+			if route != test.Expect {
+				t.Fatalf("expected route %q; got %q", test.Expect, route)
+			}
+		})
+	}
+	//
+	if got := poly.RouteFromContext(httptest.NewRequest(http.MethodGet, "/", nil).Context()); got != "" {
+		t.Fatalf("expected empty route; got %q", got)
+	}
+}
@@ -0,0 +1,81 @@
+package poly_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nofeaturesonlybugs/poly"
+)
+
+func TestSecureHeaders(t *testing.T) {
+	type Test struct {
+		Name   string
+		Opts   poly.SecureHeadersOptions
+		Expect map[string]string
+	}
+	tests := []Test{
+		{
+			Name: "defaults",
+			Expect: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "DENY",
+				"Strict-Transport-Security": "max-age=63072000; includeSubDomains",
+				"Content-Security-Policy":   "default-src 'self'",
+			},
+		},
+		{
+			Name: "overrides",
+			Opts: poly.SecureHeadersOptions{
+				FrameOptions:            "SAMEORIGIN",
+				StrictTransportSecurity: "max-age=300",
+				ContentSecurityPolicy:   "default-src 'none'",
+			},
+			Expect: map[string]string{
+				"X-Content-Type-Options":    "nosniff",
+				"X-Frame-Options":           "SAMEORIGIN",
+				"Strict-Transport-Security": "max-age=300",
+				"Content-Security-Policy":   "default-src 'none'",
+			},
+		},
+		{
+			Name: "omitted",
+			Opts: poly.SecureHeadersOptions{
+				ContentTypeOptions:      "-",
+				StrictTransportSecurity: "-",
+			},
+			Expect: map[string]string{
+				"X-Content-Type-Options":    "",
+				"X-Frame-Options":           "DENY",
+				"Strict-Transport-Security": "",
+				"Content-Security-Policy":   "default-src 'self'",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+			w := httptest.NewRecorder()
+			poly.SecureHeaders(test.Opts)(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			for name, expect := range test.Expect {
+				if _, present := w.Header()[name]; expect == "" && present {
+					t.Fatalf("expected %v to be omitted; got %q", name, w.Header().Get(name))
+				}
+				if got := w.Header().Get(name); got != expect {
+					t.Fatalf("expected %v %q; got %q", name, expect, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSecureHeadersHandlerOverride(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("X-Frame-Options", "SAMEORIGIN")
+	})
+	w := httptest.NewRecorder()
+	poly.SecureHeaders(poly.SecureHeadersOptions{})(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := w.Header().Get("X-Frame-Options"); got != "SAMEORIGIN" {
+		t.Fatalf("expected handler value SAMEORIGIN; got %q", got)
+	}
+}
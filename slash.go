@@ -0,0 +1,86 @@
+package poly
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// SlashMode selects the canonical form of request paths for RedirectTrailingSlash.
+type SlashMode int
+
+const (
+	// StripSlash redirects /path/ to /path.
+	StripSlash SlashMode = iota
+	// AppendSlash redirects /path to /path/.
+	AppendSlash
+)
+
+// patternMatcher is implemented by muxes that report the pattern matching a request, such as
+// *http.ServeMux.
+type patternMatcher interface {
+	Handler(*http.Request) (http.Handler, string)
+}
+
+// RedirectTrailingSlash returns middleware that redirects requests to the canonical form of their
+// path, as selected by mode, before they reach the wrapped handler.  The query string is preserved.
+// It is meant to wrap the mux:
+//
+//	handler := poly.RedirectTrailingSlash(poly.StripSlash)(mux)
+//
+// If the wrapped handler reports the pattern matching a request, as *http.ServeMux does, then
+// requests are only redirected when the canonical path is registered: it must match a different
+// pattern than the requested path or be the literal path of the pattern it matches.  This keeps
+// catch-all patterns from redirecting every request.  Requests for other handlers are always
+// redirected.
+//
+// GET and HEAD requests are redirected with 301 Moved Permanently; other methods receive 308
+// Permanent Redirect so clients resend the request body.
+func RedirectTrailingSlash(mode SlashMode) func(http.Handler) http.Handler {
+	return func(mux http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			// Work on the escaped path so encoded slashes such as %2F are kept.
+			path, escaped := req.URL.EscapedPath(), ""
+			switch {
+			case path == "/":
+			case mode == StripSlash && strings.HasSuffix(path, "/"):
+				escaped = strings.TrimSuffix(path, "/")
+			case mode == AppendSlash && !strings.HasSuffix(path, "/"):
+				escaped = path + "/"
+			}
+			canonical, err := url.PathUnescape(escaped)
+			// A leading "//" would redirect to another host.
+			if escaped == "" || err != nil || strings.HasPrefix(escaped, "//") || strings.HasPrefix(canonical, "//") {
+				mux.ServeHTTP(w, req)
+				return
+			}
+			if m, ok := mux.(patternMatcher); ok {
+				_, requested := m.Handler(req)
+				alt := req.Clone(req.Context())
+				alt.URL.Path, alt.URL.RawPath = canonical, escaped
+				if _, pattern := m.Handler(alt); pattern == "" || (pattern == requested && patternPath(pattern) != canonical) {
+					mux.ServeHTTP(w, req)
+					return
+				}
+			}
+			u := *req.URL
+			u.Path, u.RawPath = canonical, escaped
+			status := http.StatusPermanentRedirect
+			if req.Method == http.MethodGet || req.Method == http.MethodHead {
+				status = http.StatusMovedPermanently
+			}
+			http.Redirect(w, req, u.RequestURI(), status)
+		})
+	}
+}
+
+// patternPath returns the path portion of a ServeMux pattern of the form [METHOD ][HOST]/[PATH].
+func patternPath(pattern string) string {
+	if _, rest, ok := strings.Cut(pattern, " "); ok {
+		pattern = strings.TrimLeft(rest, " \t")
+	}
+	if n := strings.IndexByte(pattern, '/'); n >= 0 {
+		return pattern[n:]
+	}
+	return pattern
+}
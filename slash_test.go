@@ -0,0 +1,88 @@
+package poly_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nofeaturesonlybugs/poly"
+)
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.URL.Path))
+	})
+	mux := http.NewServeMux()
+	mux.Handle("GET /users", ok)
+	mux.Handle("GET /items/", ok)
+	mux.Handle("/orders", ok)
+	mux.Handle("GET /files/{name}", ok)
+	mux.Handle("/dir/", ok)
+	mux.Handle("/", ok)
+	//
+	type Test struct {
+		Name     string
+		Mode     poly.SlashMode
+		Method   string
+		Target   string
+		Status   int
+		Location string
+	}
+	tests := []Test{
+		{Name: "strip", Mode: poly.StripSlash, Target: "/users/", Status: http.StatusMovedPermanently, Location: "/users"},
+		{Name: "strip query", Mode: poly.StripSlash, Target: "/users/?a=1&b=two", Status: http.StatusMovedPermanently, Location: "/users?a=1&b=two"},
+		{Name: "strip canonical", Mode: poly.StripSlash, Target: "/users", Status: http.StatusOK},
+		{Name: "strip unregistered", Mode: poly.StripSlash, Target: "/unknown/", Status: http.StatusOK},
+		{Name: "strip root", Mode: poly.StripSlash, Target: "/", Status: http.StatusOK},
+		{Name: "strip catch-all dir", Mode: poly.StripSlash, Target: "/dir/", Status: http.StatusOK},
+		{Name: "strip catch-all dir child", Mode: poly.StripSlash, Target: "/dir/x/", Status: http.StatusOK},
+		{Name: "strip post", Mode: poly.StripSlash, Method: http.MethodPost, Target: "/orders/?a=1", Status: http.StatusPermanentRedirect, Location: "/orders?a=1"},
+		{Name: "strip post method mismatch", Mode: poly.StripSlash, Method: http.MethodPost, Target: "/users/", Status: http.StatusOK},
+		// The middleware must not redirect to //evil.com; ServeMux then cleans the path itself.
+		{Name: "strip open redirect", Mode: poly.StripSlash, Target: "//evil.com/", Status: http.StatusTemporaryRedirect, Location: "/evil.com/"},
+		{Name: "strip escaped slash", Mode: poly.StripSlash, Target: "/files/a%2Fb/", Status: http.StatusMovedPermanently, Location: "/files/a%2Fb"},
+		{Name: "strip escaped slash canonical", Mode: poly.StripSlash, Target: "/files/a%2Fb", Status: http.StatusOK},
+		{Name: "append", Mode: poly.AppendSlash, Target: "/items", Status: http.StatusMovedPermanently, Location: "/items/"},
+		{Name: "append query", Mode: poly.AppendSlash, Target: "/items?x=1", Status: http.StatusMovedPermanently, Location: "/items/?x=1"},
+		{Name: "append canonical", Mode: poly.AppendSlash, Target: "/items/", Status: http.StatusOK},
+		{Name: "append unregistered", Mode: poly.AppendSlash, Target: "/unknown", Status: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			method := test.Method
+			if method == "" {
+				method = http.MethodGet
+			}
+			w := httptest.NewRecorder()
+			poly.RedirectTrailingSlash(test.Mode)(mux).ServeHTTP(w, httptest.NewRequest(method, test.Target, nil))
+			if w.Code != test.Status {
+				t.Fatalf("expected status %v; got %v", test.Status, w.Code)
+			}
+			if got := w.Header().Get("Location"); got != test.Location {
+				t.Fatalf("expected Location %q; got %q", test.Location, got)
+			}
+		})
+	}
+}
+
+func TestRedirectTrailingSlashHandler(t *testing.T) {
+	// Handlers that do not report patterns are always redirected.
+	next := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {})
+	tests := []struct {
+		Target   string
+		Location string
+	}{
+		{"/anything/?q=1", "/anything?q=1"},
+		{"/files/a%2Fb/", "/files/a%2Fb"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		poly.RedirectTrailingSlash(poly.StripSlash)(next).ServeHTTP(w, httptest.NewRequest(http.MethodGet, test.Target, nil))
+		if w.Code != http.StatusMovedPermanently {
+			t.Fatalf("%v: expected status %v; got %v", test.Target, http.StatusMovedPermanently, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != test.Location {
+			t.Fatalf("%v: expected Location %v; got %q", test.Target, test.Location, got)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package poly
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Timeout returns middleware that applies a client requested deadline to the request context.
+//
+// The deadline is read from header as a whole number of milliseconds and clamped to limit; a missing
+// or invalid header value uses limit.  If limit is not positive then requests without a valid header
+// are passed through without a deadline and header values are not clamped.
+//
+// Handlers observe the deadline through req.Context().  Timeout does not interrupt the handler: when
+// it returns without having written anything after the deadline expired the middleware responds
+// with 504 Gateway Timeout.  A handler that ignores its context therefore delays the 504 until it
+// returns, and if it writes a response after the deadline that response is sent instead.
+func Timeout(header string, limit time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			d := limit
+			if ms, err := strconv.ParseInt(req.Header.Get(header), 10, 64); err == nil && ms > 0 {
+				// Saturate instead of overflowing into a negative duration that would skip the clamp.
+				if ms > int64(math.MaxInt64/time.Millisecond) {
+					ms = int64(math.MaxInt64 / time.Millisecond)
+				}
+				d = time.Duration(ms) * time.Millisecond
+				if limit > 0 && d > limit {
+					d = limit
+				}
+			}
+			if d <= 0 {
+				next.ServeHTTP(w, req)
+				return
+			}
+			ctx, cancel := context.WithTimeout(req.Context(), d)
+			defer cancel()
+			//
+			tw := &timeoutWriter{ResponseWriter: w}
+			next.ServeHTTP(tw, req.WithContext(ctx))
+			if !tw.wrote && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				http.Error(w, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+			}
+		})
+	}
+}
+
+// timeoutWriter records whether the wrapped handler has started a response.
+type timeoutWriter struct {
+	http.ResponseWriter
+	wrote bool
+}
+
+// WriteHeader records the response as started.
+func (w *timeoutWriter) WriteHeader(status int) {
+	w.wrote = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write records the response as started.
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.wrote = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying writer if it supports http.Flusher.
+func (w *timeoutWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wrote = true
+		f.Flush()
+	}
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,85 @@
+package poly_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nofeaturesonlybugs/poly"
+)
+
+func TestTimeout(t *testing.T) {
+	type Test struct {
+		Name   string
+		Header string
+		Limit  time.Duration
+		Wait   bool
+		Late   bool
+		Min    time.Duration
+		Max    time.Duration
+		Status int
+	}
+	tests := []Test{
+		{Name: "valid", Header: "100", Limit: time.Second, Min: 50 * time.Millisecond, Max: 100 * time.Millisecond, Status: http.StatusOK},
+		{Name: "clamped", Header: "100000", Limit: 200 * time.Millisecond, Min: 100 * time.Millisecond, Max: 200 * time.Millisecond, Status: http.StatusOK},
+		{Name: "missing", Header: "", Limit: 200 * time.Millisecond, Min: 100 * time.Millisecond, Max: 200 * time.Millisecond, Status: http.StatusOK},
+		{Name: "invalid", Header: "soon", Limit: 200 * time.Millisecond, Min: 100 * time.Millisecond, Max: 200 * time.Millisecond, Status: http.StatusOK},
+		{Name: "overflow", Header: "9300000000000", Limit: 200 * time.Millisecond, Min: 100 * time.Millisecond, Max: 200 * time.Millisecond, Status: http.StatusOK},
+		{Name: "overflow max int", Header: "9223372036854775807", Limit: 200 * time.Millisecond, Min: 100 * time.Millisecond, Max: 200 * time.Millisecond, Status: http.StatusOK},
+		{Name: "expired", Header: "10", Limit: time.Second, Wait: true, Max: 10 * time.Millisecond, Status: http.StatusGatewayTimeout},
+		{Name: "late write", Header: "10", Limit: time.Second, Late: true, Max: 10 * time.Millisecond, Status: http.StatusOK},
+	}
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			var remaining time.Duration
+			handler := poly.Timeout("X-Timeout-Ms", test.Limit)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				deadline, ok := req.Context().Deadline()
+				if !ok {
+					t.Fatal("expected deadline")
+				}
+				remaining = time.Until(deadline)
+				switch {
+				case test.Wait:
+					<-req.Context().Done()
+					return
+				case test.Late:
+					<-req.Context().Done()
+				}
+				w.Write([]byte("ok"))
+			}))
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if test.Header != "" {
+				req.Header.Set("X-Timeout-Ms", test.Header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			if remaining < test.Min || remaining > test.Max {
+				t.Fatalf("expected deadline in [%v, %v]; got %v", test.Min, test.Max, remaining)
+			}
+			if w.Code != test.Status {
+				t.Fatalf("expected status %v; got %v", test.Status, w.Code)
+			}
+		})
+	}
+}
+
+func TestTimeoutNoLimit(t *testing.T) {
+	handler := poly.Timeout("X-Timeout-Ms", 0)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := req.Context().Deadline(); ok {
+			t.Fatal("expected no deadline")
+		}
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+func TestTimeoutNoLimitOverflow(t *testing.T) {
+	handler := poly.Timeout("X-Timeout-Ms", 0)(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := req.Context().Deadline(); !ok {
+			t.Fatal("expected deadline")
+		}
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Timeout-Ms", "9223372036854775807")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}